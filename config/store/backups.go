@@ -0,0 +1,360 @@
+package store
+
+import (
+	"archive/tar"
+	gojson "encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/datarhei/core/v16/config"
+	"github.com/datarhei/core/v16/io/file"
+)
+
+// BackupInfo describes a single entry written by Backups.
+type BackupInfo struct {
+	ID string // filename of the backup, without directory, e.g. "20260726-153000-0001-v3.json"
+
+	Time          time.Time
+	CoreVersion   string
+	ConfigVersion int64
+}
+
+// backupMetadata is what's stored alongside each backup file in metadata.json, keyed by backup ID.
+type backupMetadata struct {
+	Time          time.Time `json:"time"`
+	CoreVersion   string    `json:"core_version"`
+	ConfigVersion int64     `json:"config_version"`
+}
+
+// CoreVersion is recorded in a backup's metadata whenever it is written. The main package is
+// expected to set this to the running core's version (e.g. via ldflags) during startup.
+var CoreVersion = "unknown"
+
+// Backups manages a rolling history of previous config versions, written as a side effect of a
+// store's Set(), so operators have a safety net for the destructive Set/SetActive flow the API
+// exposes. Backups are kept in a sibling directory "<path>.backups" of the config file they belong
+// to.
+type Backups struct {
+	dir  string // "<path>.backups"
+	path string // the config file this set of backups belongs to
+	keep int
+
+	// seq disambiguates backups written within the same wall-clock second, which Set() can easily
+	// produce on fast hardware or under test. It is seeded from the highest suffix already on disk
+	// so that it keeps increasing monotonically across restarts instead of restarting at 0, which
+	// would otherwise let a new run's backups sort before an older run's within the same second.
+	seq uint64
+
+	// afterRestore, if set, is called once Restore has atomically swapped the new file into place,
+	// so the store that owns this Backups can pick up the restored config and notify its reloadFn.
+	// It is nil when a Backups is used standalone, outside of a Store.
+	afterRestore func() error
+}
+
+// NewBackups creates a Backups manager for the config file at path, keeping at most keep backups.
+// A keep of 0 or less defaults to 10.
+func NewBackups(path string, keep int) *Backups {
+	if keep <= 0 {
+		keep = 10
+	}
+
+	b := &Backups{
+		dir:  path + ".backups",
+		path: path,
+		keep: keep,
+	}
+
+	b.seq = b.highestExistingSeq()
+
+	return b
+}
+
+// highestExistingSeq scans b.dir for backup files already on disk and returns the highest sequence
+// suffix found, 0 if there are none. Backup filenames look like "20060102-150405-0001-v3.json".
+func (b *Backups) highestExistingSeq() uint64 {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return 0
+	}
+
+	var highest uint64
+
+	for _, e := range entries {
+		parts := strings.Split(e.Name(), "-")
+		if len(parts) != 4 {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if seq > highest {
+			highest = seq
+		}
+	}
+
+	return highest
+}
+
+// save writes cfg as a new backup and prunes old ones beyond the configured retention. It is called
+// with the previous config right after a new one has been persisted by Set().
+func (b *Backups) save(cfg *config.Config) error {
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return err
+	}
+
+	seq := atomic.AddUint64(&b.seq, 1)
+	id := fmt.Sprintf("%s-%04d-v%d.json", time.Now().Format("20060102-150405"), seq%10000, cfg.Data.Version)
+
+	jsondata, err := gojson.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(b.dir, id), jsondata, 0o644); err != nil {
+		return err
+	}
+
+	meta, err := b.readMetadata()
+	if err != nil {
+		return err
+	}
+
+	meta[id] = backupMetadata{
+		Time:          time.Now(),
+		CoreVersion:   CoreVersion,
+		ConfigVersion: cfg.Data.Version,
+	}
+
+	if err := b.writeMetadata(meta); err != nil {
+		return err
+	}
+
+	return b.prune(meta)
+}
+
+// prune removes the oldest backups (by ID, which sorts chronologically) beyond b.keep.
+func (b *Backups) prune(meta map[string]backupMetadata) error {
+	ids := make([]string, 0, len(meta))
+	for id := range meta {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for len(ids) > b.keep {
+		id := ids[0]
+		ids = ids[1:]
+
+		os.Remove(filepath.Join(b.dir, id))
+		delete(meta, id)
+	}
+
+	return b.writeMetadata(meta)
+}
+
+func (b *Backups) readMetadata() (map[string]backupMetadata, error) {
+	meta := map[string]backupMetadata{}
+
+	data, err := os.ReadFile(filepath.Join(b.dir, "metadata.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+
+		return nil, err
+	}
+
+	if err := gojson.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+func (b *Backups) writeMetadata(meta map[string]backupMetadata) error {
+	data, err := gojson.MarshalIndent(meta, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(b.dir, "metadata.json"), data, 0o644)
+}
+
+// List returns all known backups, oldest first.
+func (b *Backups) List() ([]BackupInfo, error) {
+	meta, err := b.readMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(meta))
+	for id := range meta {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	infos := make([]BackupInfo, 0, len(ids))
+	for _, id := range ids {
+		m := meta[id]
+		infos = append(infos, BackupInfo{
+			ID:            id,
+			Time:          m.Time,
+			CoreVersion:   m.CoreVersion,
+			ConfigVersion: m.ConfigVersion,
+		})
+	}
+
+	return infos, nil
+}
+
+// Restore makes the backup with the given ID the active config file again, atomically swapping it
+// in via the same tmpfile+rename path the stores use.
+func (b *Backups) Restore(id string) error {
+	if strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid backup id '%s'", id)
+	}
+
+	meta, err := b.readMetadata()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := meta[id]; !ok {
+		return fmt.Errorf("backup '%s' not found", id)
+	}
+
+	jsondata, err := os.ReadFile(filepath.Join(b.dir, id))
+	if err != nil {
+		return err
+	}
+
+	if _, err := migrate(jsondata); err != nil {
+		return fmt.Errorf("backup '%s' is not a valid config: %w", id, err)
+	}
+
+	dir, filename := filepath.Split(b.path)
+
+	tmpfile, err := os.CreateTemp(dir, filename)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(jsondata); err != nil {
+		return err
+	}
+
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	if err := file.Rename(tmpfile.Name(), b.path); err != nil {
+		return err
+	}
+
+	if b.afterRestore != nil {
+		return b.afterRestore()
+	}
+
+	return nil
+}
+
+// Export writes the current config file plus all known backups and their metadata as a single tar
+// stream to w, suitable for disaster recovery.
+func (b *Backups) Export(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+
+	if err := addFileToTar(tw, b.path, "config.json"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		if err := addFileToTar(tw, filepath.Join(b.dir, e.Name()), filepath.Join("backups", e.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addFileToTar(tw *tar.Writer, path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(data)),
+	}); err != nil {
+		return err
+	}
+
+	_, err = tw.Write(data)
+
+	return err
+}
+
+// Import reads a tar stream produced by Export and restores the config file and its backup history
+// from it.
+func (b *Backups) Import(r io.Reader) error {
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+
+		var target string
+		if header.Name == "config.json" {
+			target = b.path
+		} else if strings.HasPrefix(header.Name, "backups/") {
+			target = filepath.Join(b.dir, strings.TrimPrefix(header.Name, "backups/"))
+		} else {
+			continue
+		}
+
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,16 +1,23 @@
 package store
 
 import (
+	"bytes"
+	gocrypto "crypto/sha256"
 	gojson "encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/datarhei/core/v16/config"
 	v1 "github.com/datarhei/core/v16/config/v1"
 	v2 "github.com/datarhei/core/v16/config/v2"
 	"github.com/datarhei/core/v16/encoding/json"
 	"github.com/datarhei/core/v16/io/file"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
 )
 
 type jsonStore struct {
@@ -19,6 +26,13 @@ type jsonStore struct {
 	data map[string]*config.Config
 
 	reloadFn func()
+
+	lock     sync.Mutex
+	lastHash [32]byte
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+
+	backups *Backups
 }
 
 // NewJSONStore will read a JSON config file from the given path. After successfully reading it in, it will be written
@@ -34,17 +48,149 @@ func NewJSON(path string, reloadFn func()) (Store, error) {
 
 	c.data["base"] = config.New()
 
-	if err := c.load(c.data["base"]); err != nil {
+	skipStore, err := c.load(c.data["base"])
+	if err != nil {
 		return nil, fmt.Errorf("failed to read JSON from '%s': %w", path, err)
 	}
 
-	if err := c.store(c.data["base"]); err != nil {
-		return nil, fmt.Errorf("failed to write JSON to '%s': %w", path, err)
+	if !skipStore {
+		if err := c.store(c.data["base"]); err != nil {
+			return nil, fmt.Errorf("failed to write JSON to '%s': %w", path, err)
+		}
+	}
+
+	return c, nil
+}
+
+// NewJSONWithWatch behaves like NewJSON, but additionally watches path on disk with fsnotify and calls
+// reloadFn whenever the file changes. Changes made by the store itself (the atomic tmpfile+rename writes
+// performed by Set) are ignored by comparing the content hash of the file before firing, so reloadFn is
+// only invoked for changes made by something else, e.g. an operator editing config.json directly or a
+// Kubernetes ConfigMap being pushed to disk.
+func NewJSONWithWatch(path string, reloadFn func()) (Store, error) {
+	s, err := NewJSON(path, reloadFn)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.(*jsonStore)
+
+	if len(path) == 0 {
+		return c, nil
+	}
+
+	if jsondata, err := os.ReadFile(path); err == nil {
+		c.lastHash = gocrypto.Sum256(jsondata)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher for '%s': %w", path, err)
+	}
+
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch '%s': %w", path, err)
 	}
 
+	c.watcher = watcher
+	c.done = make(chan struct{})
+
+	go c.watch()
+
 	return c, nil
 }
 
+// NewJSONWithBackups behaves like NewJSON, but additionally keeps the last keep versions of the
+// config around in "<path>.backups", written on every successful Set(). Use the returned store's
+// Backups() method to list, restore, export or import them.
+func NewJSONWithBackups(path string, reloadFn func(), keep int) (Store, error) {
+	s, err := NewJSON(path, reloadFn)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.(*jsonStore)
+	c.backups = NewBackups(path, keep)
+	c.backups.afterRestore = c.reloadFromDisk
+
+	return c, nil
+}
+
+// reloadFromDisk re-reads c.path into the in-memory base config and triggers reloadFn, so a restored
+// backup (or any other out-of-band write to c.path) actually becomes the active config instead of
+// just sitting on disk until some unrelated reload happens.
+func (c *jsonStore) reloadFromDisk() error {
+	if _, err := c.load(c.data["base"]); err != nil {
+		return err
+	}
+
+	return c.Reload()
+}
+
+// watch debounces fsnotify events for c.path (~500ms) and invokes reloadFn when the file's content
+// actually changed, ignoring writes the store itself performed via store().
+func (c *jsonStore) watch() {
+	var timer *time.Timer
+
+	fire := func() {
+		jsondata, err := os.ReadFile(c.path)
+		if err != nil {
+			return
+		}
+
+		hash := gocrypto.Sum256(jsondata)
+
+		c.lock.Lock()
+		changed := hash != c.lastHash
+		c.lastHash = hash
+		c.lock.Unlock()
+
+		if changed {
+			c.Reload()
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Clean(event.Name) != filepath.Clean(c.path) {
+				continue
+			}
+
+			if timer == nil {
+				timer = time.AfterFunc(500*time.Millisecond, fire)
+			} else {
+				timer.Reset(500 * time.Millisecond)
+			}
+		case _, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-c.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+	}
+}
+
+// Close stops the filesystem watcher, if any. It is safe to call on a store created with NewJSON.
+func (c *jsonStore) Close() error {
+	if c.watcher == nil {
+		return nil
+	}
+
+	close(c.done)
+
+	return c.watcher.Close()
+}
+
 func (c *jsonStore) Get() *config.Config {
 	return c.data["base"].Clone()
 }
@@ -56,15 +202,29 @@ func (c *jsonStore) Set(d *config.Config) error {
 
 	data := d.Clone()
 
+	previous := c.data["base"]
+
 	if err := c.store(data); err != nil {
 		return fmt.Errorf("failed to write JSON to '%s': %w", c.path, err)
 	}
 
 	c.data["base"] = data
 
+	if c.backups != nil {
+		if err := c.backups.save(previous); err != nil {
+			return fmt.Errorf("failed to write backup of '%s': %w", c.path, err)
+		}
+	}
+
 	return nil
 }
 
+// Backups returns the backup manager for this store, or nil if it wasn't created with
+// NewJSONWithBackups.
+func (c *jsonStore) Backups() *Backups {
+	return c.backups
+}
+
 func (c *jsonStore) GetActive() *config.Config {
 	if x, ok := c.data["merged"]; ok {
 		return x.Clone()
@@ -101,34 +261,53 @@ func (c *jsonStore) Reload() error {
 	return nil
 }
 
-func (c *jsonStore) load(cfg *config.Config) error {
+// load reads the config from c.path into cfg. It returns whether the caller can skip writing the
+// config back to disk, which is the case if the on-disk bytes already parse as version 3 and
+// re-marshalling the loaded config produces byte-identical output. This avoids bumping the file's
+// mtime (and defeating file-change detectors such as the fsnotify watcher) on every open.
+func (c *jsonStore) load(cfg *config.Config) (bool, error) {
 	if len(c.path) == 0 {
-		return nil
+		return true, nil
 	}
 
 	if _, err := os.Stat(c.path); os.IsNotExist(err) {
-		return nil
+		return false, nil
 	}
 
 	jsondata, err := os.ReadFile(c.path)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if len(jsondata) == 0 {
-		return nil
+		return false, nil
+	}
+
+	version := DataVersion{}
+	if err := gojson.Unmarshal(jsondata, &version); err != nil {
+		return false, json.FormatError(jsondata, err)
 	}
 
 	data, err := migrate(jsondata)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	cfg.Data = *data
 
 	cfg.UpdatedAt = cfg.CreatedAt
 
-	return nil
+	if version.Version != 3 {
+		log.Info().Msgf("migrated from v%d to v3", version.Version)
+		return false, nil
+	}
+
+	remarshaled, err := gojson.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(jsondata, remarshaled), nil
 }
 
 func (c *jsonStore) store(data *config.Config) error {
@@ -162,6 +341,13 @@ func (c *jsonStore) store(data *config.Config) error {
 		return err
 	}
 
+	// Record the hash of what we just wrote so the watcher (if any) recognizes this write as our
+	// own and doesn't fire reloadFn for it.
+	hash := gocrypto.Sum256(jsondata)
+	c.lock.Lock()
+	c.lastHash = hash
+	c.lock.Unlock()
+
 	return nil
 }
 
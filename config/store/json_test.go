@@ -0,0 +1,43 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONStoreNoopOpenKeepsMtime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	s, err := NewJSON(path, nil)
+	if err != nil {
+		t.Fatalf("NewJSON: %v", err)
+	}
+	s.(*jsonStore).Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	mtime := info.ModTime()
+
+	// Make sure a rewrite, if it happened, would be observable.
+	time.Sleep(10 * time.Millisecond)
+
+	s, err = NewJSON(path, nil)
+	if err != nil {
+		t.Fatalf("second NewJSON: %v", err)
+	}
+	s.(*jsonStore).Close()
+
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("second Stat: %v", err)
+	}
+
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("expected mtime to be unchanged across a no-op open, got %s want %s", info.ModTime(), mtime)
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"os"
+	"strings"
+)
+
+// envLayer is a Layer that sources configuration overrides from environment variables prefixed CORE_.
+// An environment variable CORE_A_B_C maps to the config path "a.b.c". Values are kept as plain strings
+// rather than guessing a type: blindly inferring bool/int/float from the literal (e.g. treating "0" or
+// "1" as a bool) produces JSON of the wrong type for whatever the target field actually is, which makes
+// migrate() fail to unmarshal and breaks the merge for every layer, not just this one. The config
+// unmarshaler is left to coerce the string into the field's real type.
+type envLayer struct {
+	prefix string
+}
+
+// NewEnvLayer returns a Layer that reads its values from environment variables with the given prefix
+// (e.g. "CORE_"). It has no way to observe environment changes at runtime, so Watch is a no-op.
+func NewEnvLayer(prefix string) Layer {
+	return &envLayer{
+		prefix: prefix,
+	}
+}
+
+func (l *envLayer) Name() string {
+	return "env"
+}
+
+func (l *envLayer) Watch(fn func()) {}
+
+func (l *envLayer) Load() (map[string]interface{}, error) {
+	overrides := map[string]interface{}{}
+
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+
+		if !strings.HasPrefix(key, l.prefix) {
+			continue
+		}
+
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(key, l.prefix)), "_")
+		setPath(overrides, path, value)
+	}
+
+	return overrides, nil
+}
+
+// setPath sets value at the nested location described by path inside m, creating intermediate maps
+// as needed.
+func setPath(m map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 0 {
+		return
+	}
+
+	if len(path) == 1 {
+		m[path[0]] = value
+		return
+	}
+
+	next, ok := m[path[0]].(map[string]interface{})
+	if !ok {
+		next = map[string]interface{}{}
+		m[path[0]] = next
+	}
+
+	setPath(next, path[1:], value)
+}
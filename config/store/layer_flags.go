@@ -0,0 +1,36 @@
+package store
+
+import (
+	"strings"
+)
+
+// flagsLayer is a Layer that sources configuration overrides from CLI flags that were already parsed
+// by the caller. It takes precedence over every other layer when it is passed last to NewLayered.
+type flagsLayer struct {
+	overrides map[string]interface{}
+}
+
+// NewFlagsLayer returns a Layer backed by a pre-parsed set of flag overrides, keyed the same way as the
+// config path they should override (e.g. "http.port"). CLI flags don't change at runtime, so Watch is a
+// no-op.
+func NewFlagsLayer(overrides map[string]interface{}) Layer {
+	return &flagsLayer{
+		overrides: overrides,
+	}
+}
+
+func (l *flagsLayer) Name() string {
+	return "flags"
+}
+
+func (l *flagsLayer) Watch(fn func()) {}
+
+func (l *flagsLayer) Load() (map[string]interface{}, error) {
+	nested := map[string]interface{}{}
+
+	for k, v := range l.overrides {
+		setPath(nested, strings.Split(k, "."), v)
+	}
+
+	return nested, nil
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/datarhei/core/v16/config"
+)
+
+// Layer is a single source of configuration data. Layers are combined by a layeredStore in order
+// of increasing precedence to produce the effective configuration.
+type Layer interface {
+	// Name returns a human readable name of the layer, used for logging and debugging.
+	Name() string
+
+	// Load reads the current overrides of this layer as a nested map containing only the keys this
+	// layer actually sets (e.g. {"http": {"port": 8080}}). It is called once on startup and again
+	// whenever Watch's callback fires. A layer that currently has nothing to say returns an empty
+	// (or nil) map, never a fully populated zero-value config.
+	Load() (map[string]interface{}, error)
+
+	// Watch registers fn to be called whenever the underlying source of this layer changes. Layers
+	// that can't observe changes (e.g. CLI flags) may treat this as a no-op.
+	Watch(fn func())
+}
+
+// layeredStore composes multiple Layer sources (defaults, a file, environment variables, CLI flags, ...)
+// and produces the effective *config.Config via a deep-merge with a well-defined precedence. Layers added
+// later take precedence over layers added earlier.
+type layeredStore struct {
+	base Store
+
+	layers []Layer
+
+	data map[string]*config.Config
+
+	reloadFn func()
+}
+
+// NewLayered wraps base (the file-backed Store of record) with additional layers such as environment
+// variables prefixed CORE_ and CLI flag overrides. Get() still returns the file layer only, so the admin
+// UI continues to round-trip cleanly. GetActive() returns the result of merging all layers on top of the
+// file in the order they are passed, each later layer taking precedence over the earlier ones; passing
+// the env layer before the flags layer gives defaults < file < env < flags.
+func NewLayered(base Store, reloadFn func(), layers ...Layer) (Store, error) {
+	c := &layeredStore{
+		base:     base,
+		layers:   layers,
+		data:     make(map[string]*config.Config),
+		reloadFn: reloadFn,
+	}
+
+	for _, l := range c.layers {
+		layer := l
+		layer.Watch(func() {
+			c.remerge()
+			c.Reload()
+		})
+	}
+
+	if err := c.remerge(); err != nil {
+		return nil, fmt.Errorf("failed to merge config layers: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *layeredStore) Get() *config.Config {
+	return c.base.Get()
+}
+
+func (c *layeredStore) Set(d *config.Config) error {
+	if err := c.base.Set(d); err != nil {
+		return err
+	}
+
+	return c.remerge()
+}
+
+func (c *layeredStore) GetActive() *config.Config {
+	if x, ok := c.data["merged"]; ok {
+		return x.Clone()
+	}
+
+	return c.base.Get()
+}
+
+func (c *layeredStore) SetActive(d *config.Config) error {
+	d.Validate(true)
+
+	if d.HasErrors() {
+		return fmt.Errorf("configuration data has errors after validation")
+	}
+
+	data := d.Clone()
+
+	c.data["merged"] = data
+
+	return nil
+}
+
+func (c *layeredStore) Reload() error {
+	if c.reloadFn == nil {
+		return nil
+	}
+
+	c.reloadFn()
+
+	return nil
+}
+
+// Close closes the base store. The additional layers (env, flags, ...) don't own any resources that
+// need to be released.
+func (c *layeredStore) Close() error {
+	return c.base.Close()
+}
+
+// remerge re-runs the deep-merge of all layers (file layer from base, then the additional layers in the
+// order they were provided) and stores the result as the active config. It is called whenever the file
+// layer is written to or any other layer reports a change via Watch.
+func (c *layeredStore) remerge() error {
+	merged, err := marshalToMap(c.base.Get())
+	if err != nil {
+		return fmt.Errorf("failed to marshal file layer: %w", err)
+	}
+
+	for _, l := range c.layers {
+		overlay, err := l.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load layer '%s': %w", l.Name(), err)
+		}
+
+		if len(overlay) == 0 {
+			continue
+		}
+
+		deepMerge(merged, overlay)
+	}
+
+	jsondata, err := gojson.Marshal(merged)
+	if err != nil {
+		return err
+	}
+
+	cfgdata, err := migrate(jsondata)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.New()
+	cfg.Data = *cfgdata
+
+	c.data["merged"] = cfg
+
+	return nil
+}
+
+func marshalToMap(v interface{}) (map[string]interface{}, error) {
+	jsondata, err := gojson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	if err := gojson.Unmarshal(jsondata, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// deepMerge merges src into dst in place. Values in src take precedence over dst, except that nested
+// objects are merged key by key rather than replaced wholesale.
+func deepMerge(dst, src map[string]interface{}) {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		srcVal := src[k]
+
+		dstVal, ok := dst[k]
+		if !ok {
+			dst[k] = srcVal
+			continue
+		}
+
+		dstMap, dstIsMap := dstVal.(map[string]interface{})
+		srcMap, srcIsMap := srcVal.(map[string]interface{})
+
+		if dstIsMap && srcIsMap {
+			deepMerge(dstMap, srcMap)
+			continue
+		}
+
+		dst[k] = srcVal
+	}
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// NewFromPath will create a Store based on the file extension of path. Files ending in ".yaml" or ".yml"
+// will be backed by a YAML store, everything else (including an empty path) defaults to the JSON store.
+// This allows operators to choose the on-disk format of the config file without having to change any
+// call site that just wants "a Store for this path".
+func NewFromPath(path string, reloadFn func()) (Store, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return NewYAML(path, reloadFn)
+	default:
+		return NewJSON(path, reloadFn)
+	}
+}
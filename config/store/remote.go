@@ -0,0 +1,212 @@
+package store
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/datarhei/core/v16/config"
+)
+
+// remoteDriver is the minimal interface a remote key/value backend has to provide for remoteStore to
+// persist and hot-sync a config blob on top of it.
+type remoteDriver interface {
+	// Load fetches the raw, currently stored bytes for the configured key. It returns (nil, nil) if
+	// the key doesn't exist yet.
+	Load() ([]byte, error)
+
+	// Save persists data under the configured key.
+	Save(data []byte) error
+
+	// Subscribe registers fn to be called whenever another node writes a new value to the key, e.g.
+	// via Redis pub/sub or an etcd watch.
+	Subscribe(fn func()) error
+
+	// Close releases any connections held by the driver.
+	Close() error
+}
+
+// remoteStore is a Store backed by a remoteDriver (Redis, etcd, ...). It persists the *config.Config
+// blob to a single key and uses the same migrate() pipeline on read as jsonStore, so old serialized
+// versions upgrade transparently regardless of which node wrote them.
+type remoteStore struct {
+	driver remoteDriver
+
+	lock sync.Mutex
+	data map[string]*config.Config
+
+	reloadFn func()
+}
+
+// newRemoteStore wraps driver in a Store, loading the current value (if any) and subscribing to
+// changes so that multiple instances sharing the same key stay in sync.
+func newRemoteStore(driver remoteDriver, reloadFn func()) (Store, error) {
+	c := &remoteStore{
+		driver:   driver,
+		data:     make(map[string]*config.Config),
+		reloadFn: reloadFn,
+	}
+
+	c.data["base"] = config.New()
+
+	if err := c.reload(); err != nil {
+		return nil, fmt.Errorf("failed to load remote config: %w", err)
+	}
+
+	if err := c.driver.Subscribe(func() {
+		if err := c.reload(); err != nil {
+			return
+		}
+
+		c.Reload()
+	}); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to remote config changes: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *remoteStore) Get() *config.Config {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.data["base"].Clone()
+}
+
+func (c *remoteStore) Set(d *config.Config) error {
+	if d.HasErrors() {
+		return fmt.Errorf("configuration data has errors after validation")
+	}
+
+	data := d.Clone()
+
+	jsondata, err := gojson.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := c.driver.Save(jsondata); err != nil {
+		return fmt.Errorf("failed to save remote config: %w", err)
+	}
+
+	c.lock.Lock()
+	c.data["base"] = data
+	c.lock.Unlock()
+
+	return nil
+}
+
+func (c *remoteStore) GetActive() *config.Config {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if x, ok := c.data["merged"]; ok {
+		return x.Clone()
+	}
+
+	if x, ok := c.data["base"]; ok {
+		return x.Clone()
+	}
+
+	return nil
+}
+
+func (c *remoteStore) SetActive(d *config.Config) error {
+	d.Validate(true)
+
+	if d.HasErrors() {
+		return fmt.Errorf("configuration data has errors after validation")
+	}
+
+	data := d.Clone()
+
+	c.lock.Lock()
+	c.data["merged"] = data
+	c.lock.Unlock()
+
+	return nil
+}
+
+func (c *remoteStore) Reload() error {
+	if c.reloadFn == nil {
+		return nil
+	}
+
+	c.reloadFn()
+
+	return nil
+}
+
+func (c *remoteStore) Close() error {
+	return c.driver.Close()
+}
+
+// reload fetches the current value from the driver and, if present, swaps it in as the base config
+// under c.lock. It is safe to call concurrently, in particular from the driver's Subscribe callback,
+// which runs on a goroutine of its own.
+func (c *remoteStore) reload() error {
+	jsondata, err := c.driver.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(jsondata) == 0 {
+		return nil
+	}
+
+	data, err := migrate(jsondata)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.New()
+	cfg.Data = *data
+	cfg.UpdatedAt = cfg.CreatedAt
+
+	c.lock.Lock()
+	c.data["base"] = cfg
+	c.lock.Unlock()
+
+	return nil
+}
+
+// NewFromURL creates a Store from a URL-style address, selecting the backend by scheme:
+//
+//	redis://host:6379/core-config
+//	etcd://host:2379/core-config
+//
+// The path component is used as the key under which the config blob is stored. This allows operators
+// to run multiple datarhei/core instances against a single shared configuration without choosing
+// between Redis and etcd at compile time.
+func NewFromURL(rawurl string, reloadFn func()) (Store, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote store URL '%s': %w", rawurl, err)
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if len(key) == 0 {
+		key = "core-config"
+	}
+
+	switch u.Scheme {
+	case "redis":
+		driver, err := newRedisDriver(u.Host, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redis store: %w", err)
+		}
+
+		return newRemoteStore(driver, reloadFn)
+	case "etcd":
+		driver, err := newEtcdDriver(u.Host, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create etcd store: %w", err)
+		}
+
+		return newRemoteStore(driver, reloadFn)
+	default:
+		return nil, fmt.Errorf("unsupported remote store scheme '%s'", u.Scheme)
+	}
+}
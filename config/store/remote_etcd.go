@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdDriver is a remoteDriver backed by a single etcd key. Change notifications are delivered via
+// etcd's native Watch API on that key, so no separate pub/sub channel is needed.
+type etcdDriver struct {
+	client *clientv3.Client
+	key    string
+
+	cancelWatch context.CancelFunc
+
+	// lastWriteRevision is the etcd revision of the last Put this driver itself performed. Watch
+	// events carrying that exact ModRevision are our own write coming back around and are ignored,
+	// so Subscribe's callback only fires when another node wrote the key.
+	lock              sync.Mutex
+	lastWriteRevision int64
+}
+
+func newEtcdDriver(addr string, key string) (*etcdDriver, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{addr},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &etcdDriver{
+		client: client,
+		key:    key,
+	}, nil
+}
+
+func (d *etcdDriver) Load() ([]byte, error) {
+	resp, err := d.client.Get(context.Background(), d.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	return resp.Kvs[0].Value, nil
+}
+
+func (d *etcdDriver) Save(data []byte) error {
+	resp, err := d.client.Put(context.Background(), d.key, string(data))
+	if err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	d.lastWriteRevision = resp.Header.Revision
+	d.lock.Unlock()
+
+	return nil
+}
+
+func (d *etcdDriver) Subscribe(fn func()) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelWatch = cancel
+
+	watch := d.client.Watch(ctx, d.key)
+
+	go func() {
+		for wresp := range watch {
+			foreign := false
+
+			for _, ev := range wresp.Events {
+				d.lock.Lock()
+				own := ev.Kv.ModRevision == d.lastWriteRevision
+				d.lock.Unlock()
+
+				if !own {
+					foreign = true
+					break
+				}
+			}
+
+			if foreign {
+				fn()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (d *etcdDriver) Close() error {
+	if d.cancelWatch != nil {
+		d.cancelWatch()
+	}
+
+	return d.client.Close()
+}
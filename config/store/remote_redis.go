@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisDriver is a remoteDriver backed by a single Redis key plus a pub/sub channel of the same name
+// that is used to notify other instances when the key has been updated.
+type redisDriver struct {
+	client  *redis.Client
+	key     string
+	channel string
+
+	// id is prepended to every published notification so Subscribe can recognize and ignore
+	// messages this instance published itself, per the "only fire when another node writes" rule.
+	id string
+
+	pubsub *redis.PubSub
+}
+
+func newRedisDriver(addr string, key string) (*redisDriver, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr: addr,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &redisDriver{
+		client:  client,
+		key:     key,
+		channel: key + ":changed",
+		id:      uuid.NewString(),
+	}, nil
+}
+
+func (d *redisDriver) Load() ([]byte, error) {
+	data, err := d.client.Get(context.Background(), d.key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+func (d *redisDriver) Save(data []byte) error {
+	if err := d.client.Set(context.Background(), d.key, data, 0).Err(); err != nil {
+		return err
+	}
+
+	return d.client.Publish(context.Background(), d.channel, d.id).Err()
+}
+
+func (d *redisDriver) Subscribe(fn func()) error {
+	d.pubsub = d.client.Subscribe(context.Background(), d.channel)
+
+	go func() {
+		for msg := range d.pubsub.Channel() {
+			if strings.TrimSpace(msg.Payload) == d.id {
+				continue
+			}
+
+			fn()
+		}
+	}()
+
+	return nil
+}
+
+func (d *redisDriver) Close() error {
+	if d.pubsub != nil {
+		d.pubsub.Close()
+	}
+
+	return d.client.Close()
+}
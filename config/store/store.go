@@ -0,0 +1,28 @@
+package store
+
+import "github.com/datarhei/core/v16/config"
+
+// Store is a generic interface for persisting and retrieving the core configuration.
+type Store interface {
+	// Get returns the current configuration as it is stored on disk (or other persistent medium),
+	// without any active overrides applied.
+	Get() *config.Config
+
+	// Set persists the given configuration, replacing what Get() will return afterwards.
+	Set(data *config.Config) error
+
+	// GetActive returns the currently active configuration, i.e. the configuration that is actually
+	// in use. It may differ from what Get() returns, e.g. because of overrides set with SetActive.
+	GetActive() *config.Config
+
+	// SetActive sets the currently active configuration without persisting it. It will get lost
+	// after a restart.
+	SetActive(data *config.Config) error
+
+	// Reload triggers the reload function that has been registered with the store, if any.
+	Reload() error
+
+	// Close stops any background activity associated with the store, e.g. a file watcher. A closed
+	// Store must not be used afterwards.
+	Close() error
+}
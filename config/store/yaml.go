@@ -0,0 +1,198 @@
+package store
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datarhei/core/v16/config"
+	"github.com/datarhei/core/v16/io/file"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlStore struct {
+	path string
+
+	data map[string]*config.Config
+
+	reloadFn func()
+}
+
+// NewYAML will read a YAML config file from the given path. After successfully reading it in, it will be written
+// back to the path. The returned error will be nil if everything went fine.
+// If the path doesn't exist, a default YAML config file will be written to that path.
+// The returned ConfigStore can be used to retrieve or write the config.
+func NewYAML(path string, reloadFn func()) (Store, error) {
+	c := &yamlStore{
+		path:     path,
+		data:     make(map[string]*config.Config),
+		reloadFn: reloadFn,
+	}
+
+	c.data["base"] = config.New()
+
+	if err := c.load(c.data["base"]); err != nil {
+		return nil, fmt.Errorf("failed to read YAML from '%s': %w", path, err)
+	}
+
+	if err := c.store(c.data["base"]); err != nil {
+		return nil, fmt.Errorf("failed to write YAML to '%s': %w", path, err)
+	}
+
+	return c, nil
+}
+
+func (c *yamlStore) Get() *config.Config {
+	return c.data["base"].Clone()
+}
+
+func (c *yamlStore) Set(d *config.Config) error {
+	if d.HasErrors() {
+		return fmt.Errorf("configuration data has errors after validation")
+	}
+
+	data := d.Clone()
+
+	if err := c.store(data); err != nil {
+		return fmt.Errorf("failed to write YAML to '%s': %w", c.path, err)
+	}
+
+	c.data["base"] = data
+
+	return nil
+}
+
+func (c *yamlStore) GetActive() *config.Config {
+	if x, ok := c.data["merged"]; ok {
+		return x.Clone()
+	}
+
+	if x, ok := c.data["base"]; ok {
+		return x.Clone()
+	}
+
+	return nil
+}
+
+func (c *yamlStore) SetActive(d *config.Config) error {
+	d.Validate(true)
+
+	if d.HasErrors() {
+		return fmt.Errorf("configuration data has errors after validation")
+	}
+
+	data := d.Clone()
+
+	c.data["merged"] = data
+
+	return nil
+}
+
+func (c *yamlStore) Reload() error {
+	if c.reloadFn == nil {
+		return nil
+	}
+
+	c.reloadFn()
+
+	return nil
+}
+
+func (c *yamlStore) Close() error {
+	return nil
+}
+
+func (c *yamlStore) load(cfg *config.Config) error {
+	if len(c.path) == 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(c.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	yamldata, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	if len(yamldata) == 0 {
+		return nil
+	}
+
+	// yaml.v3 unmarshals into a map with arbitrary key types, while the migrate()
+	// pipeline expects JSON. Go through an intermediate map so that the existing
+	// version detector and upgraders can be reused unmodified.
+	var generic map[string]interface{}
+
+	if err := yaml.Unmarshal(yamldata, &generic); err != nil {
+		return err
+	}
+
+	jsondata, err := gojson.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	data, err := migrate(jsondata)
+	if err != nil {
+		return err
+	}
+
+	cfg.Data = *data
+
+	cfg.UpdatedAt = cfg.CreatedAt
+
+	return nil
+}
+
+func (c *yamlStore) store(data *config.Config) error {
+	if len(c.path) == 0 {
+		return nil
+	}
+
+	// yaml.v3 ignores "json" struct tags and lowercases field names on its own, so marshalling data
+	// directly would write keys that don't match what load() (and the JSON-tagged config structs)
+	// expect on the way back in. Go JSON->map before handing off to yaml, the reverse of load(),
+	// which goes YAML->map->JSON before handing off to migrate(), so the round-trip is symmetric.
+	jsondata, err := gojson.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var generic map[string]interface{}
+
+	if err := gojson.Unmarshal(jsondata, &generic); err != nil {
+		return err
+	}
+
+	yamldata, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	dir, filename := filepath.Split(c.path)
+
+	tmpfile, err := os.CreateTemp(dir, filename)
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(yamldata); err != nil {
+		return err
+	}
+
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	if err := file.Rename(tmpfile.Name(), c.path); err != nil {
+		return err
+	}
+
+	return nil
+}